@@ -0,0 +1,84 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "io"
+    "log/slog"
+    "time"
+
+    "nw/pkg/nntp"
+)
+
+// connFlags are the flags shared by every subcommand that opens its
+// own NNTP connection: server address, auth, TLS, proxy, timeout, and
+// logging.
+type connFlags struct {
+    server       *string
+    port         *int
+    username     *string
+    password     *string
+    useTLS       *bool
+    insecure     *bool
+    starttls     *bool
+    proxyAddr    *string
+    readTimeout  *int
+    logLevel     *string
+    logFormat    *string
+    logFile      *string
+    logMaxSizeMB *int
+    logRetain    *int
+}
+
+// registerConnFlags adds connFlags to fs.
+func registerConnFlags(fs *flag.FlagSet) *connFlags {
+    return &connFlags{
+        server:       fs.String("server", "news.tcpreset.net", "NNTP server address"),
+        port:         fs.Int("port", 119, "NNTP server port"),
+        username:     fs.String("user", "", "NNTP username"),
+        password:     fs.String("pass", "", "NNTP password"),
+        useTLS:       fs.Bool("tls", false, "Use an implicit TLS connection"),
+        insecure:     fs.Bool("insecure", false, "Skip TLS certificate verification"),
+        starttls:     fs.Bool("starttls", false, "Upgrade a plaintext connection via STARTTLS (fails if the server does not advertise it)"),
+        proxyAddr:    fs.String("proxy", "", "SOCKS proxy (e.g., 127.0.0.1:9050)"),
+        readTimeout:  fs.Int("timeout", 1200, "Read timeout in seconds"),
+        logLevel:     fs.String("log-level", "info", "Log level: debug, info, warn, error"),
+        logFormat:    fs.String("log-format", "text", "Log format: text, json"),
+        logFile:      fs.String("log-file", "", "Additionally write logs to this rotating file (empty disables it)"),
+        logMaxSizeMB: fs.Int("log-max-size", 100, "Rotate -log-file once it exceeds this size, in megabytes"),
+        logRetain:    fs.Int("log-retain", 5, "Number of rotated, gzipped -log-file segments to keep"),
+    }
+}
+
+// newLogger builds the logger described by cf's logging flags.
+func (cf *connFlags) newLogger() (*slog.Logger, io.Closer, error) {
+    return newLogger(*cf.logLevel, *cf.logFormat, *cf.logFile, *cf.logMaxSizeMB, *cf.logRetain)
+}
+
+// connect dials, negotiates TLS/compression, and authenticates using
+// cf's settings, tracing the session through logger. The caller is
+// responsible for closing the returned connection.
+func (cf *connFlags) connect(logger *slog.Logger) (*nntp.Conn, error) {
+    conn, err := dialNNTP(*cf.server, *cf.port, *cf.useTLS, *cf.insecure, *cf.proxyAddr, logger)
+    if err != nil {
+        return nil, fmt.Errorf("connection failed: %v", err)
+    }
+
+    if err := conn.SetReadTimeout(time.Duration(*cf.readTimeout) * time.Second); err != nil {
+        logger.Warn("couldn't set timeout", "error", err)
+    }
+
+    if err := negotiateCapabilities(conn, *cf.server, *cf.starttls, *cf.insecure); err != nil {
+        conn.Quit()
+        return nil, fmt.Errorf("capability negotiation failed: %v", err)
+    }
+
+    if *cf.username != "" {
+        if err := conn.Authenticate(*cf.username, *cf.password); err != nil {
+            conn.Quit()
+            return nil, fmt.Errorf("authentication failed: %v", err)
+        }
+    }
+
+    return conn, nil
+}
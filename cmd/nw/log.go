@@ -0,0 +1,55 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "log/slog"
+    "os"
+    "strings"
+
+    "nw/pkg/logrotate"
+)
+
+// newLogger builds the logger nw uses for warnings, resume messages,
+// and (at debug level) NNTP protocol tracing. Output always goes to
+// stderr; if logFile is non-empty it is additionally written to a
+// rotating file there. The returned io.Closer is the rotating file, if
+// any, and should be closed on exit.
+func newLogger(level, format, logFile string, maxSizeMB, retain int) (*slog.Logger, io.Closer, error) {
+    var lvl slog.Level
+    switch strings.ToLower(level) {
+    case "debug":
+        lvl = slog.LevelDebug
+    case "info":
+        lvl = slog.LevelInfo
+    case "warn", "warning":
+        lvl = slog.LevelWarn
+    case "error":
+        lvl = slog.LevelError
+    default:
+        return nil, nil, fmt.Errorf("unknown -log-level %q", level)
+    }
+
+    w := io.Writer(os.Stderr)
+    var closer io.Closer
+    if logFile != "" {
+        rw, err := logrotate.New(logFile, int64(maxSizeMB)*1024*1024, retain)
+        if err != nil {
+            return nil, nil, err
+        }
+        w = io.MultiWriter(os.Stderr, rw)
+        closer = rw
+    }
+
+    opts := &slog.HandlerOptions{Level: lvl}
+    var handler slog.Handler
+    switch strings.ToLower(format) {
+    case "text":
+        handler = slog.NewTextHandler(w, opts)
+    case "json":
+        handler = slog.NewJSONHandler(w, opts)
+    default:
+        return nil, nil, fmt.Errorf("unknown -log-format %q", format)
+    }
+    return slog.New(handler), closer, nil
+}
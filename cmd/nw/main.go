@@ -0,0 +1,305 @@
+// Command nw fetches recent Usenet articles from an NNTP server and
+// writes them to the sink selected by -output (stdout by default).
+package main
+
+import (
+    "crypto/tls"
+    "flag"
+    "fmt"
+    "log/slog"
+    "os"
+    "strings"
+    "time"
+
+    "golang.org/x/net/proxy"
+
+    "nw/pkg/nntp"
+    "nw/pkg/sink"
+    "nw/pkg/statestore"
+)
+
+func printUsage() {
+    fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS]\n\nOptions:\n", os.Args[0])
+    flag.PrintDefaults()
+    fmt.Fprintf(os.Stderr, "\nExample:\n  %s -group alt.test -days 1 -latest\n", os.Args[0])
+}
+
+// dialNNTP connects to the server, optionally through a SOCKS proxy,
+// and returns a ready-to-use nntp.Conn with logger attached for
+// command/response tracing. TLS certificates are verified unless
+// insecure is set.
+func dialNNTP(server string, port int, useTLS, insecure bool, proxyAddr string, logger *slog.Logger) (*nntp.Conn, error) {
+    address := fmt.Sprintf("%s:%d", server, port)
+
+    var conn *nntp.Conn
+    var err error
+    switch {
+    case proxyAddr != "":
+        dialer, derr := proxy.SOCKS5("tcp", proxyAddr, nil, proxy.Direct)
+        if derr != nil {
+            return nil, fmt.Errorf("proxy connection failed: %v", derr)
+        }
+        raw, derr := dialer.Dial("tcp", address)
+        if derr != nil {
+            return nil, fmt.Errorf("proxy dial failed: %v", derr)
+        }
+        if useTLS {
+            conn, err = nntp.NewConn(tls.Client(raw, &tls.Config{
+                InsecureSkipVerify: insecure,
+                ServerName:         server,
+            }))
+        } else {
+            conn, err = nntp.NewConn(raw)
+        }
+    case useTLS:
+        conn, err = nntp.DialTLS("tcp", address, &tls.Config{
+            ServerName:         server,
+            InsecureSkipVerify: insecure,
+        })
+    default:
+        conn, err = nntp.Dial("tcp", address)
+    }
+    if err != nil {
+        return nil, err
+    }
+    conn.SetLogger(logger)
+    return conn, nil
+}
+
+// negotiateCapabilities probes the server's CAPABILITIES and, in order,
+// upgrades to STARTTLS if requested (failing if the server does not
+// advertise it), then transparently enables COMPRESS DEFLATE if
+// advertised. Capabilities are re-probed
+// after a STARTTLS upgrade, since what a server advertises may change
+// once the session is encrypted.
+func negotiateCapabilities(conn *nntp.Conn, server string, starttls, insecure bool) error {
+    caps, err := conn.Capabilities()
+    if err != nil {
+        return fmt.Errorf("capabilities: %v", err)
+    }
+
+    if starttls {
+        if !hasCapability(caps, "STARTTLS") {
+            return fmt.Errorf("server does not advertise STARTTLS")
+        }
+        if err := conn.StartTLS(&tls.Config{
+            ServerName:         server,
+            InsecureSkipVerify: insecure,
+        }); err != nil {
+            return err
+        }
+        if caps, err = conn.Capabilities(); err != nil {
+            return fmt.Errorf("capabilities: %v", err)
+        }
+    }
+
+    if hasCapability(caps, "COMPRESS DEFLATE") {
+        if err := conn.EnableCompression(); err != nil {
+            return fmt.Errorf("compress: %v", err)
+        }
+    }
+    return nil
+}
+
+func hasCapability(caps []string, name string) bool {
+    for _, cap := range caps {
+        if strings.EqualFold(strings.TrimSpace(cap), name) {
+            return true
+        }
+    }
+    return false
+}
+
+func parseDate(dateStr string) (time.Time, error) {
+    dateStr = strings.TrimSpace(dateStr)
+    if idx := strings.Index(dateStr, " ("); idx > 0 {
+        dateStr = dateStr[:idx]
+    }
+    return time.Parse("Mon, 2 Jan 2006 15:04:05 -0700", dateStr)
+}
+
+// writeArticle assembles a's raw bytes and parsed headers and hands
+// them to s.
+func writeArticle(s sink.Sink, a *nntp.Article) error {
+    raw := []byte(strings.Join(a.Header, "\r\n") + "\r\n\r\n" + a.Body)
+    return s.Write(raw, sink.ParseHeaders(a.Header))
+}
+
+func getRecentArticles(conn *nntp.Conn, group string, days int, useLatest bool, maxBatchSize int, store *statestore.Store, out sink.Sink, logger *slog.Logger, limiter *rateLimiter) (int, error) {
+    _, first, last, err := conn.Group(group)
+    if err != nil {
+        return 0, fmt.Errorf("group command failed: %v", err)
+    }
+
+    if useLatest {
+        highWater, err := store.HighWater(group)
+        if err != nil {
+            logger.Warn("failed to load state", "group", group, "error", err)
+        } else if highWater > 0 {
+            if highWater >= last {
+                logger.Info("no new articles available since last fetch", "group", group, "last_fetched", highWater)
+                return 0, nil // No new articles to fetch
+            }
+            if highWater >= first && highWater < last {
+                first = highWater + 1
+                logger.Info("resuming from article", "group", group, "article", first, "last_fetched", highWater)
+            }
+        }
+    }
+
+    var fetched int
+    cutoff := time.Now().AddDate(0, 0, -days)
+    batchStart := first
+
+    for batchStart <= last {
+        batchEnd := batchStart + maxBatchSize - 1
+        if batchEnd > last {
+            batchEnd = last
+        }
+
+        limiter.wait()
+        overview, err := conn.Over(fmt.Sprintf("%d-%d", batchStart, batchEnd))
+        if err != nil {
+            return fetched, fmt.Errorf("xover command failed: %v", err)
+        }
+
+        for _, line := range overview {
+            if days > 0 {
+                date, err := parseDate(line.Date)
+                if err != nil || date.Before(cutoff) {
+                    continue
+                }
+            }
+
+            if seen, err := store.Seen(group, line.MessageID); err != nil {
+                logger.Warn("statestore lookup failed", "message_id", line.MessageID, "error", err)
+            } else if seen {
+                continue
+            }
+
+            limiter.wait()
+            article, err := conn.Article(fmt.Sprintf("%d", line.Number))
+            if err != nil {
+                logger.Warn("failed to fetch article", "group", group, "number", line.Number, "error", err)
+                continue
+            }
+            if err := writeArticle(out, article); err != nil {
+                logger.Warn("failed to write article", "group", group, "number", line.Number, "error", err)
+                continue
+            }
+            if err := store.MarkSeen(group, line.MessageID, time.Now()); err != nil {
+                logger.Warn("failed to record article in statestore", "message_id", line.MessageID, "error", err)
+            }
+            fetched++
+        }
+
+        if useLatest && batchEnd > first {
+            if err := store.SetHighWater(group, batchEnd); err != nil {
+                logger.Warn("failed to save state", "group", group, "error", err)
+            }
+        }
+
+        batchStart = batchEnd + 1
+    }
+
+    if fetched == 0 {
+        return 0, fmt.Errorf("no articles found matching criteria")
+    }
+
+    return fetched, nil
+}
+
+func main() {
+    if len(os.Args) > 1 {
+        switch os.Args[1] {
+        case "state":
+            runStateCommand(os.Args[2:])
+            return
+        case "post":
+            runPostCommand(os.Args[2:])
+            return
+        }
+    }
+
+    fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+    cf := registerConnFlags(fs)
+    group := fs.String("group", "alt.anonymous.messages", "Newsgroup(s) to download from: comma-separated, or @file with one per line")
+    days := fs.Int("days", 1, "Download articles from last N days (0 for all)")
+    latest := fs.Bool("latest", false, "Only fetch articles newer than last run")
+    maxBatchSize := fs.Int("batch", 500, "Maximum batch size for XOVER command")
+    conns := fs.Int("conns", 1, "Number of concurrent NNTP connections to fetch with")
+    rps := fs.Float64("rps", 0, "Maximum XOVER/ARTICLE commands per second across all connections (0 for unlimited)")
+    output := fs.String("output", "stdout", "Where to write fetched articles: stdout, mbox:<path>, maildir:<path>, amqp://..., nats://...")
+    stateDB := fs.String("statedb", "nw-state.db", "Path to the statestore database tracking fetched articles")
+    fs.Parse(os.Args[1:])
+
+    logger, logCloser, err := cf.newLogger()
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        os.Exit(1)
+    }
+    if logCloser != nil {
+        defer logCloser.Close()
+    }
+
+    groups, err := parseGroups(*group)
+    if err != nil {
+        logger.Error("invalid -group", "error", err)
+        os.Exit(1)
+    }
+
+    out, err := sink.New(*output)
+    if err != nil {
+        logger.Error("failed to open output sink", "error", err)
+        os.Exit(1)
+    }
+    defer out.Close()
+
+    store, err := statestore.Open(*stateDB)
+    if err != nil {
+        logger.Error("failed to open statestore", "error", err)
+        os.Exit(1)
+    }
+    defer store.Close()
+
+    if *conns > 1 || len(groups) > 1 {
+        cfg := poolConfig{
+            server:       *cf.server,
+            port:         *cf.port,
+            useTLS:       *cf.useTLS,
+            insecure:     *cf.insecure,
+            starttls:     *cf.starttls,
+            proxyAddr:    *cf.proxyAddr,
+            username:     *cf.username,
+            password:     *cf.password,
+            readTimeout:  time.Duration(*cf.readTimeout) * time.Second,
+            maxBatchSize: *maxBatchSize,
+            days:         *days,
+            latest:       *latest,
+            limiter:      newRateLimiter(*rps),
+            progress:     newProgress(),
+            store:        store,
+            logger:       logger,
+        }
+        go cfg.progress.report()
+        err := runParallelFetch(groups, *conns, cfg, out)
+        cfg.progress.stop()
+        if err != nil {
+            logger.Error("fetch failed", "error", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    conn, err := cf.connect(logger)
+    if err != nil {
+        logger.Error(err.Error())
+        os.Exit(1)
+    }
+    defer conn.Quit()
+
+    if _, err := getRecentArticles(conn, groups[0], *days, *latest, *maxBatchSize, store, out, logger, newRateLimiter(*rps)); err != nil {
+        logger.Error("fetch failed", "error", err)
+        os.Exit(1)
+    }
+}
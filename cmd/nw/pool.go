@@ -0,0 +1,347 @@
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "log/slog"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "nw/pkg/nntp"
+    "nw/pkg/sink"
+    "nw/pkg/statestore"
+)
+
+// parseGroups expands -group into a list of newsgroup names. A leading
+// "@" names a file containing one group per line (blank lines and "#"
+// comments are ignored); otherwise the value is a comma-separated list.
+func parseGroups(spec string) ([]string, error) {
+    if strings.HasPrefix(spec, "@") {
+        f, err := os.Open(spec[1:])
+        if err != nil {
+            return nil, fmt.Errorf("failed to open group file: %v", err)
+        }
+        defer f.Close()
+
+        var groups []string
+        scanner := bufio.NewScanner(f)
+        for scanner.Scan() {
+            line := strings.TrimSpace(scanner.Text())
+            if line == "" || strings.HasPrefix(line, "#") {
+                continue
+            }
+            groups = append(groups, line)
+        }
+        if err := scanner.Err(); err != nil {
+            return nil, fmt.Errorf("failed to read group file: %v", err)
+        }
+        if len(groups) == 0 {
+            return nil, fmt.Errorf("group file %q names no newsgroups", spec[1:])
+        }
+        return groups, nil
+    }
+
+    var groups []string
+    for _, g := range strings.Split(spec, ",") {
+        if g = strings.TrimSpace(g); g != "" {
+            groups = append(groups, g)
+        }
+    }
+    if len(groups) == 0 {
+        return nil, fmt.Errorf("-group names no newsgroups")
+    }
+    return groups, nil
+}
+
+// rateLimiter caps the rate of XOVER and ARTICLE commands issued across
+// every worker in the pool to -rps requests per second; other commands
+// (GROUP, CAPABILITIES, AUTHINFO, QUIT) are not counted or delayed. A nil *rateLimiter
+// (the -rps 0 default) imposes no limit.
+type rateLimiter struct {
+    interval time.Duration
+    mu       sync.Mutex
+    next     time.Time
+}
+
+func newRateLimiter(rps float64) *rateLimiter {
+    if rps <= 0 {
+        return nil
+    }
+    return &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+func (r *rateLimiter) wait() {
+    if r == nil {
+        return
+    }
+    r.mu.Lock()
+    now := time.Now()
+    if now.Before(r.next) {
+        wait := r.next.Sub(now)
+        r.next = r.next.Add(r.interval)
+        r.mu.Unlock()
+        time.Sleep(wait)
+        return
+    }
+    r.next = now.Add(r.interval)
+    r.mu.Unlock()
+}
+
+// progress reports fetch counts across all pool workers on stderr
+// roughly once per second, so a long multi-group run shows signs of
+// life.
+type progress struct {
+    mu      sync.Mutex
+    groups  map[string]bool
+    fetched int
+    done    chan struct{}
+}
+
+func newProgress() *progress {
+    return &progress{groups: make(map[string]bool), done: make(chan struct{})}
+}
+
+func (p *progress) addGroup(name string) {
+    p.mu.Lock()
+    p.groups[name] = true
+    p.mu.Unlock()
+}
+
+func (p *progress) addFetched(n int) {
+    if n == 0 {
+        return
+    }
+    p.mu.Lock()
+    p.fetched += n
+    p.mu.Unlock()
+}
+
+func (p *progress) report() {
+    ticker := time.NewTicker(time.Second)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ticker.C:
+            p.mu.Lock()
+            fmt.Fprintf(os.Stderr, "progress: %d group(s), %d article(s) fetched\n", len(p.groups), p.fetched)
+            p.mu.Unlock()
+        case <-p.done:
+            return
+        }
+    }
+}
+
+func (p *progress) stop() { close(p.done) }
+
+// poolConfig bundles the connection parameters every pool worker needs
+// to dial and authenticate its own connection.
+type poolConfig struct {
+    server, proxyAddr  string
+    port               int
+    useTLS             bool
+    insecure           bool
+    starttls           bool
+    username, password string
+    readTimeout        time.Duration
+    maxBatchSize       int
+    days               int
+    latest             bool
+    limiter            *rateLimiter
+    progress           *progress
+    store              *statestore.Store
+    logger             *slog.Logger
+}
+
+func (cfg poolConfig) dial() (*nntp.Conn, error) {
+    conn, err := dialNNTP(cfg.server, cfg.port, cfg.useTLS, cfg.insecure, cfg.proxyAddr, cfg.logger)
+    if err != nil {
+        return nil, err
+    }
+    if err := conn.SetReadTimeout(cfg.readTimeout); err != nil {
+        conn.Quit()
+        return nil, err
+    }
+    if err := negotiateCapabilities(conn, cfg.server, cfg.starttls, cfg.insecure); err != nil {
+        conn.Quit()
+        return nil, err
+    }
+    if cfg.username != "" {
+        if err := conn.Authenticate(cfg.username, cfg.password); err != nil {
+            conn.Quit()
+            return nil, err
+        }
+    }
+    return conn, nil
+}
+
+// groupJob is one XOVER/ARTICLE batch within a group. Batches are
+// handed out on a shared channel so that, for a single large group,
+// idle workers pick up the next range as soon as they finish one
+// rather than sitting on a per-group assignment.
+type groupJob struct {
+    group      string
+    start, end int
+}
+
+// partitionJobs splits the article range [first, last] for group into
+// groupJobs of at most batchSize articles each, in ascending order.
+func partitionJobs(group string, first, last, batchSize int) []groupJob {
+    var jobs []groupJob
+    for start := first; start <= last; start += batchSize {
+        end := start + batchSize - 1
+        if end > last {
+            end = last
+        }
+        jobs = append(jobs, groupJob{group: group, start: start, end: end})
+    }
+    return jobs
+}
+
+// runParallelFetch fetches every group in groups using a pool of n
+// NNTP connections, writing each article to out as it arrives. Writes
+// to out are serialized, since most Sink implementations (a single
+// mbox file, a single AMQP channel) aren't safe for concurrent use.
+// Dedup and high-water tracking go through cfg.store, which is safe for
+// concurrent use by every worker.
+func runParallelFetch(groups []string, n int, cfg poolConfig, out sink.Sink) error {
+    if n < 1 {
+        n = 1
+    }
+
+    jobs := make(chan groupJob, 256)
+
+    var outMu sync.Mutex
+    var hwMu sync.Mutex
+    var errMu sync.Mutex
+    var firstErr error
+    recordErr := func(err error) {
+        errMu.Lock()
+        if firstErr == nil {
+            firstErr = err
+        }
+        errMu.Unlock()
+    }
+
+    var wg sync.WaitGroup
+    for i := 0; i < n; i++ {
+        wg.Add(1)
+        go func(worker int) {
+            defer wg.Done()
+            conn, err := cfg.dial()
+            if err != nil {
+                recordErr(fmt.Errorf("connection %d: %v", worker, err))
+                return
+            }
+            defer conn.Quit()
+
+            cutoff := time.Now().AddDate(0, 0, -cfg.days)
+            var selected string
+            for j := range jobs {
+                if j.group != selected {
+                    if _, _, _, err := conn.Group(j.group); err != nil {
+                        cfg.logger.Warn("group failed", "group", j.group, "error", err)
+                        continue
+                    }
+                    selected = j.group
+                }
+
+                cfg.limiter.wait()
+                overview, err := conn.Over(fmt.Sprintf("%d-%d", j.start, j.end))
+                if err != nil {
+                    cfg.logger.Warn("xover failed", "group", j.group, "start", j.start, "end", j.end, "error", err)
+                    continue
+                }
+
+                var fetched int
+                for _, line := range overview {
+                    if cfg.days > 0 {
+                        date, err := parseDate(line.Date)
+                        if err != nil || date.Before(cutoff) {
+                            continue
+                        }
+                    }
+                    if seen, err := cfg.store.Seen(j.group, line.MessageID); err != nil {
+                        cfg.logger.Warn("statestore lookup failed", "message_id", line.MessageID, "error", err)
+                    } else if seen {
+                        continue
+                    }
+
+                    cfg.limiter.wait()
+                    article, err := conn.Article(fmt.Sprintf("%d", line.Number))
+                    if err != nil {
+                        cfg.logger.Warn("failed to fetch article", "group", j.group, "number", line.Number, "error", err)
+                        continue
+                    }
+                    outMu.Lock()
+                    writeErr := writeArticle(out, article)
+                    outMu.Unlock()
+                    if writeErr != nil {
+                        cfg.logger.Warn("failed to write article", "group", j.group, "number", line.Number, "error", writeErr)
+                        continue
+                    }
+                    if err := cfg.store.MarkSeen(j.group, line.MessageID, time.Now()); err != nil {
+                        cfg.logger.Warn("failed to record article in statestore", "message_id", line.MessageID, "error", err)
+                    }
+                    fetched++
+                }
+                cfg.progress.addFetched(fetched)
+
+                if cfg.latest {
+                    // Batches for a group aren't necessarily finished in
+                    // order across workers, so only advance the mark,
+                    // never regress it. hwMu makes the read-and-advance
+                    // atomic across workers.
+                    hwMu.Lock()
+                    if current, err := cfg.store.HighWater(j.group); err != nil {
+                        cfg.logger.Warn("failed to load state", "group", j.group, "error", err)
+                    } else if j.end > current {
+                        if err := cfg.store.SetHighWater(j.group, j.end); err != nil {
+                            cfg.logger.Warn("failed to save state", "group", j.group, "error", err)
+                        }
+                    }
+                    hwMu.Unlock()
+                }
+            }
+        }(i)
+    }
+
+    for _, group := range groups {
+        cfg.progress.addGroup(group)
+
+        conn, err := cfg.dial()
+        if err != nil {
+            recordErr(fmt.Errorf("group %s: %v", group, err))
+            continue
+        }
+        _, first, last, err := conn.Group(group)
+        conn.Quit()
+        if err != nil {
+            recordErr(fmt.Errorf("group %s: %v", group, err))
+            continue
+        }
+
+        if cfg.latest {
+            highWater, err := cfg.store.HighWater(group)
+            if err != nil {
+                cfg.logger.Warn("failed to load state", "group", group, "error", err)
+            } else if highWater > 0 {
+                if highWater >= last {
+                    continue
+                }
+                if highWater >= first && highWater < last {
+                    first = highWater + 1
+                }
+            }
+        }
+
+        for _, j := range partitionJobs(group, first, last, cfg.maxBatchSize) {
+            jobs <- j
+        }
+    }
+    close(jobs)
+    wg.Wait()
+
+    return firstErr
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestParseGroupsCommaSeparated(t *testing.T) {
+    groups, err := parseGroups(" alt.test , alt.binaries.test ,,")
+    if err != nil {
+        t.Fatalf("parseGroups: %v", err)
+    }
+    want := []string{"alt.test", "alt.binaries.test"}
+    if !reflect.DeepEqual(groups, want) {
+        t.Fatalf("parseGroups = %#v, want %#v", groups, want)
+    }
+}
+
+func TestParseGroupsEmptySpecIsAnError(t *testing.T) {
+    if _, err := parseGroups(""); err == nil {
+        t.Fatal("expected error for -group \"\"")
+    }
+    if _, err := parseGroups(" , ,"); err == nil {
+        t.Fatal("expected error for a spec with no actual group names")
+    }
+}
+
+func TestParseGroupsFromFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "groups.txt")
+    content := "alt.test\n# a comment\n\nalt.binaries.test\n"
+    if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+        t.Fatalf("WriteFile: %v", err)
+    }
+
+    groups, err := parseGroups("@" + path)
+    if err != nil {
+        t.Fatalf("parseGroups: %v", err)
+    }
+    want := []string{"alt.test", "alt.binaries.test"}
+    if !reflect.DeepEqual(groups, want) {
+        t.Fatalf("parseGroups = %#v, want %#v", groups, want)
+    }
+}
+
+func TestParseGroupsEmptyFileIsAnError(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "groups.txt")
+    if err := os.WriteFile(path, []byte("# nothing here\n"), 0644); err != nil {
+        t.Fatalf("WriteFile: %v", err)
+    }
+    if _, err := parseGroups("@" + path); err == nil {
+        t.Fatal("expected error for a group file naming no newsgroups")
+    }
+}
+
+func TestPartitionJobsSplitsIntoBatches(t *testing.T) {
+    jobs := partitionJobs("alt.test", 1, 250, 100)
+    want := []groupJob{
+        {group: "alt.test", start: 1, end: 100},
+        {group: "alt.test", start: 101, end: 200},
+        {group: "alt.test", start: 201, end: 250},
+    }
+    if !reflect.DeepEqual(jobs, want) {
+        t.Fatalf("partitionJobs = %#v, want %#v", jobs, want)
+    }
+}
+
+func TestPartitionJobsEmptyRange(t *testing.T) {
+    if jobs := partitionJobs("alt.test", 100, 50, 10); jobs != nil {
+        t.Fatalf("partitionJobs = %#v, want nil for an empty range", jobs)
+    }
+}
+
+func TestPartitionJobsSingleBatch(t *testing.T) {
+    jobs := partitionJobs("alt.test", 5, 8, 100)
+    want := []groupJob{{group: "alt.test", start: 5, end: 8}}
+    if !reflect.DeepEqual(jobs, want) {
+        t.Fatalf("partitionJobs = %#v, want %#v", jobs, want)
+    }
+}
+
+func TestRateLimiterNilIsUnlimited(t *testing.T) {
+    var r *rateLimiter
+    start := time.Now()
+    for i := 0; i < 100; i++ {
+        r.wait()
+    }
+    if time.Since(start) > 50*time.Millisecond {
+        t.Fatalf("nil rateLimiter.wait() took %v, want effectively instant", time.Since(start))
+    }
+}
+
+func TestRateLimiterSpacesCalls(t *testing.T) {
+    r := newRateLimiter(100) // 100 rps -> 10ms apart
+    start := time.Now()
+    for i := 0; i < 5; i++ {
+        r.wait()
+    }
+    elapsed := time.Since(start)
+    if elapsed < 30*time.Millisecond {
+        t.Fatalf("5 calls at 100rps took %v, want at least ~40ms", elapsed)
+    }
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "os/exec"
+    "strings"
+
+    "nw/pkg/nntp"
+)
+
+// requiredHeaders lists the RFC 5322 / RFC 5536 headers a Usenet
+// article must have before nw will submit it.
+var requiredHeaders = []string{"From", "Subject", "Newsgroups"}
+
+// readMessage reads an RFC 5322 message from r and splits it into
+// header lines (in wire order) and a raw body, the same way
+// splitArticle in pkg/nntp handles an article read off the wire. The
+// header lines are split manually, rather than through net/mail's
+// map[string][]string, because Go randomizes map iteration order and
+// that would make the posted article's header order non-deterministic
+// across runs.
+func readMessage(r io.Reader) (headers []string, body string, err error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, "", fmt.Errorf("failed to read message: %v", err)
+    }
+
+    lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+    for i, line := range lines {
+        if line == "" {
+            return lines[:i], strings.Join(lines[i+1:], "\r\n"), nil
+        }
+    }
+    return nil, "", fmt.Errorf("message has no header/body separator")
+}
+
+func validateHeaders(headers []string) error {
+    present := make(map[string]bool)
+    for _, h := range headers {
+        if name, _, ok := strings.Cut(h, ":"); ok {
+            present[strings.TrimSpace(name)] = true
+        }
+    }
+    var missing []string
+    for _, want := range requiredHeaders {
+        if !present[want] {
+            missing = append(missing, want)
+        }
+    }
+    if len(missing) > 0 {
+        return fmt.Errorf("missing required header(s): %s", strings.Join(missing, ", "))
+    }
+    return nil
+}
+
+// signBody clearsigns body with gpg using the given key identifier
+// (a key ID, fingerprint, or uid that gpg can resolve via --local-user).
+func signBody(body, signKey string) (string, error) {
+    cmd := exec.Command("gpg", "--batch", "--local-user", signKey, "--clearsign")
+    cmd.Stdin = strings.NewReader(body)
+    var out bytes.Buffer
+    cmd.Stdout = &out
+    cmd.Stderr = os.Stderr
+    if err := cmd.Run(); err != nil {
+        return "", fmt.Errorf("gpg --clearsign failed: %v", err)
+    }
+    return out.String(), nil
+}
+
+// runPost reads an article from stdin, validates it, optionally signs
+// it, and submits it to conn with POST.
+func runPost(conn *nntp.Conn, signKey string) error {
+    headers, body, err := readMessage(bufio.NewReader(os.Stdin))
+    if err != nil {
+        return err
+    }
+    if err := validateHeaders(headers); err != nil {
+        return err
+    }
+
+    if signKey != "" {
+        signed, err := signBody(body, signKey)
+        if err != nil {
+            return fmt.Errorf("signing failed: %v", err)
+        }
+        body = signed
+    }
+
+    a := &nntp.Article{Header: headers, Body: body}
+    if err := conn.Post(a); err != nil {
+        return fmt.Errorf("post failed: %v", err)
+    }
+    return nil
+}
+
+// runPostCommand implements "nw post": it connects using the same
+// connection flags the default fetch mode accepts, then reads an
+// article from stdin and submits it via POST.
+func runPostCommand(args []string) {
+    fs := flag.NewFlagSet("post", flag.ExitOnError)
+    cf := registerConnFlags(fs)
+    signKey := fs.String("sign-key", "", "gpg local-user key to clearsign posted articles with")
+    fs.Parse(args)
+
+    logger, logCloser, err := cf.newLogger()
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        os.Exit(1)
+    }
+    if logCloser != nil {
+        defer logCloser.Close()
+    }
+
+    conn, err := cf.connect(logger)
+    if err != nil {
+        logger.Error(err.Error())
+        os.Exit(1)
+    }
+    defer conn.Quit()
+
+    if err := runPost(conn, *signKey); err != nil {
+        logger.Error("post failed", "error", err)
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestReadMessagePreservesHeaderOrder(t *testing.T) {
+    msg := "Newsgroups: alt.test\r\nFrom: a@b.c\r\nSubject: hi\r\n\r\nbody line\r\n"
+    for i := 0; i < 5; i++ {
+        headers, body, err := readMessage(strings.NewReader(msg))
+        if err != nil {
+            t.Fatalf("readMessage: %v", err)
+        }
+        want := []string{"Newsgroups: alt.test", "From: a@b.c", "Subject: hi"}
+        if len(headers) != len(want) {
+            t.Fatalf("headers = %#v, want %#v", headers, want)
+        }
+        for j, h := range headers {
+            if h != want[j] {
+                t.Fatalf("headers = %#v, want %#v", headers, want)
+            }
+        }
+        if body != "body line\r\n" {
+            t.Fatalf("body = %q, want %q", body, "body line\r\n")
+        }
+    }
+}
+
+func TestReadMessageNoSeparator(t *testing.T) {
+    if _, _, err := readMessage(strings.NewReader("Subject: hi\r\nno blank line")); err == nil {
+        t.Fatal("expected error for message with no header/body separator")
+    }
+}
+
+func TestValidateHeadersMissing(t *testing.T) {
+    err := validateHeaders([]string{"Subject: hi"})
+    if err == nil {
+        t.Fatal("expected error for missing required headers")
+    }
+    if !strings.Contains(err.Error(), "From") || !strings.Contains(err.Error(), "Newsgroups") {
+        t.Fatalf("error = %q, want it to name the missing headers", err)
+    }
+}
+
+func TestValidateHeadersComplete(t *testing.T) {
+    err := validateHeaders([]string{"From: a@b.c", "Subject: hi", "Newsgroups: alt.test"})
+    if err != nil {
+        t.Fatalf("validateHeaders: %v", err)
+    }
+}
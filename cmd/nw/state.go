@@ -0,0 +1,88 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "log/slog"
+    "os"
+    "time"
+
+    "nw/pkg/statestore"
+)
+
+// runStateCommand dispatches "nw state <show|prune>".
+func runStateCommand(args []string) {
+    if len(args) == 0 {
+        fmt.Fprintln(os.Stderr, "Usage: nw state <show|prune> [options]")
+        os.Exit(1)
+    }
+
+    switch args[0] {
+    case "show":
+        runStateShow(args[1:])
+    case "prune":
+        runStatePrune(args[1:])
+    default:
+        fmt.Fprintf(os.Stderr, "nw state: unknown subcommand %q\n", args[0])
+        os.Exit(1)
+    }
+}
+
+func runStateShow(args []string) {
+    fs := flag.NewFlagSet("state show", flag.ExitOnError)
+    dbPath := fs.String("statedb", "nw-state.db", "Path to the statestore database")
+    group := fs.String("group", "", "Limit to a single group (default: every group on record)")
+    fs.Parse(args)
+
+    store, err := statestore.Open(*dbPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        os.Exit(1)
+    }
+    defer store.Close()
+
+    groups := []string{*group}
+    if *group == "" {
+        groups, err = store.Groups()
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(1)
+        }
+    }
+
+    logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+    for _, g := range groups {
+        highWater, err := store.HighWater(g)
+        if err != nil {
+            logger.Warn("failed to read state", "group", g, "error", err)
+            continue
+        }
+        count, err := store.Count(g)
+        if err != nil {
+            logger.Warn("failed to read state", "group", g, "error", err)
+            continue
+        }
+        fmt.Printf("%s\thigh=%d\tseen=%d\n", g, highWater, count)
+    }
+}
+
+func runStatePrune(args []string) {
+    fs := flag.NewFlagSet("state prune", flag.ExitOnError)
+    dbPath := fs.String("statedb", "nw-state.db", "Path to the statestore database")
+    olderThan := fs.Duration("older-than", 30*24*time.Hour, "Remove seen-article records older than this")
+    fs.Parse(args)
+
+    store, err := statestore.Open(*dbPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        os.Exit(1)
+    }
+    defer store.Close()
+
+    removed, err := store.Prune(*olderThan)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        os.Exit(1)
+    }
+    fmt.Printf("pruned %d record(s)\n", removed)
+}
@@ -0,0 +1,144 @@
+// Package logrotate provides a size-based rotating io.Writer, in the
+// spirit of lumberjack: once the active file exceeds a configured size
+// it is sealed, gzipped aside, and a fresh file is started, with only
+// the newest of a configurable number of segments kept.
+package logrotate
+
+import (
+    "compress/gzip"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "sync"
+    "time"
+)
+
+// Writer is an io.WriteCloser that rotates its backing file once it
+// exceeds maxBytes. It is safe for concurrent use.
+type Writer struct {
+    path     string
+    maxBytes int64
+    retain   int
+
+    mu   sync.Mutex
+    file *os.File
+    size int64
+}
+
+// New opens, creating if necessary, a rotating writer at path. A
+// maxBytes of 0 disables rotation; retain caps how many gzipped
+// segments are kept once rotation starts producing them.
+func New(path string, maxBytes int64, retain int) (*Writer, error) {
+    f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+    if err != nil {
+        return nil, fmt.Errorf("logrotate: open: %v", err)
+    }
+    info, err := f.Stat()
+    if err != nil {
+        f.Close()
+        return nil, fmt.Errorf("logrotate: stat: %v", err)
+    }
+    return &Writer{path: path, maxBytes: maxBytes, retain: retain, file: f, size: info.Size()}, nil
+}
+
+// Write implements io.Writer, rotating first if p would push the file
+// past maxBytes.
+func (w *Writer) Write(p []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    if w.maxBytes > 0 && w.size+int64(len(p)) > w.maxBytes {
+        if err := w.rotate(); err != nil {
+            return 0, err
+        }
+    }
+    n, err := w.file.Write(p)
+    w.size += int64(n)
+    return n, err
+}
+
+// rotate seals the current file under a timestamped name, gzips it,
+// opens a fresh file at path, and prunes segments beyond retain.
+func (w *Writer) rotate() error {
+    if err := w.file.Close(); err != nil {
+        return fmt.Errorf("logrotate: close: %v", err)
+    }
+
+    sealed := fmt.Sprintf("%s.%s", w.path, time.Now().UTC().Format("20060102T150405Z"))
+    if err := os.Rename(w.path, sealed); err != nil {
+        return fmt.Errorf("logrotate: rotate: %v", err)
+    }
+    if err := gzipAndRemove(sealed); err != nil {
+        return fmt.Errorf("logrotate: compress: %v", err)
+    }
+    if err := w.prune(); err != nil {
+        return err
+    }
+
+    f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+    if err != nil {
+        return fmt.Errorf("logrotate: reopen: %v", err)
+    }
+    w.file = f
+    w.size = 0
+    return nil
+}
+
+// gzipAndRemove compresses path to path+".gz" and removes the
+// uncompressed original.
+func gzipAndRemove(path string) error {
+    in, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer in.Close()
+
+    out, err := os.Create(path + ".gz")
+    if err != nil {
+        return err
+    }
+    gz := gzip.NewWriter(out)
+    if _, err := io.Copy(gz, in); err != nil {
+        gz.Close()
+        out.Close()
+        return err
+    }
+    if err := gz.Close(); err != nil {
+        out.Close()
+        return err
+    }
+    if err := out.Close(); err != nil {
+        return err
+    }
+    return os.Remove(path)
+}
+
+// prune removes all but the retain newest gzipped segments for path.
+func (w *Writer) prune() error {
+    if w.retain <= 0 {
+        return nil
+    }
+    matches, err := filepath.Glob(w.path + ".*.gz")
+    if err != nil {
+        return fmt.Errorf("logrotate: glob: %v", err)
+    }
+    if len(matches) <= w.retain {
+        return nil
+    }
+    sort.Strings(matches) // Timestamp suffixes sort chronologically.
+    for _, old := range matches[:len(matches)-w.retain] {
+        if err := os.Remove(old); err != nil {
+            return fmt.Errorf("logrotate: prune: %v", err)
+        }
+    }
+    return nil
+}
+
+// Close closes the underlying file.
+func (w *Writer) Close() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    return w.file.Close()
+}
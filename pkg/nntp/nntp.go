@@ -0,0 +1,458 @@
+// Package nntp implements a small client for the Network News Transfer
+// Protocol (NNTP), as described in RFC 3977. It is modeled on the old
+// net/nntp package: a single Conn wraps a textproto connection and
+// exposes one method per command, with all multi-line (dot-terminated)
+// response parsing centralized in readDotLines so callers never see
+// dot-stuffing.
+package nntp
+
+import (
+    "bufio"
+    "compress/flate"
+    "crypto/tls"
+    "fmt"
+    "io"
+    "log/slog"
+    "net"
+    "net/textproto"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Error represents an error response from an NNTP server: a three-digit
+// status code together with the server's message text.
+type Error struct {
+    Code int
+    Msg  string
+}
+
+func (e *Error) Error() string {
+    return fmt.Sprintf("%03d %s", e.Code, e.Msg)
+}
+
+// ProtocolError is returned when a server's response cannot be parsed
+// as an NNTP response line at all.
+type ProtocolError string
+
+func (p ProtocolError) Error() string {
+    return string(p)
+}
+
+// Article is an NNTP article: its header lines in wire order plus the
+// raw body text, with dot-stuffing already removed.
+type Article struct {
+    Header []string
+    Body   string
+}
+
+// OverviewLine is one row of an XOVER response.
+type OverviewLine struct {
+    Number    int
+    Subject   string
+    From      string
+    Date      string
+    MessageID string
+    References string
+    Bytes     int
+    Lines     int
+}
+
+// Conn represents a connection to an NNTP server. Conn is stateful: it
+// tracks whatever group is currently selected via Group, the same way
+// the underlying protocol does.
+type Conn struct {
+    conn net.Conn
+    r    *textproto.Reader
+    w    *bufio.Writer
+
+    logger *slog.Logger
+}
+
+// SetLogger attaches a logger that every command sent and status line
+// received is reported to at debug level, with AUTHINFO PASS arguments
+// redacted. A nil logger (the default) disables this tracing.
+func (c *Conn) SetLogger(logger *slog.Logger) {
+    c.logger = logger
+}
+
+// Dial connects to an NNTP server at addr ("host:port").
+func Dial(network, addr string) (*Conn, error) {
+    c, err := net.Dial(network, addr)
+    if err != nil {
+        return nil, err
+    }
+    return newConn(c)
+}
+
+// DialTLS connects to an NNTP server at addr using an implicit TLS
+// connection (e.g. port 563).
+func DialTLS(network, addr string, config *tls.Config) (*Conn, error) {
+    c, err := tls.Dial(network, addr, config)
+    if err != nil {
+        return nil, err
+    }
+    return newConn(c)
+}
+
+// NewConn wraps an already-open, already-dialed connection (for
+// example one obtained through a SOCKS proxy) and reads the server's
+// greeting.
+func NewConn(c net.Conn) (*Conn, error) {
+    return newConn(c)
+}
+
+// SetReadTimeout bounds how long subsequent reads from the server may
+// block.
+func (c *Conn) SetReadTimeout(d time.Duration) error {
+    return c.conn.SetReadDeadline(time.Now().Add(d))
+}
+
+// newConn wraps an already-open connection and reads the server's
+// greeting. It is not used after a STARTTLS upgrade, where the
+// underlying net.Conn changes but no new greeting is sent; StartTLS
+// calls wrap directly instead.
+func newConn(c net.Conn) (*Conn, error) {
+    conn := &Conn{conn: c}
+    conn.wrap(c)
+    if _, _, err := conn.readCode(); err != nil {
+        c.Close()
+        return nil, fmt.Errorf("nntp: reading greeting: %v", err)
+    }
+    return conn, nil
+}
+
+// wrap (re)builds the buffered reader and writer over rw. It is used
+// at connection setup and again whenever the underlying stream changes
+// shape, as STARTTLS and COMPRESS both do.
+func (c *Conn) wrap(rw readWriter) {
+    c.r = textproto.NewReader(bufio.NewReader(rw))
+    c.w = bufio.NewWriter(rw)
+}
+
+// readWriter is the minimal interface wrap needs; both net.Conn and the
+// flate-wrapped streams used by EnableCompression satisfy it.
+type readWriter interface {
+    Read(p []byte) (int, error)
+    Write(p []byte) (int, error)
+}
+
+// flateWriter flushes the underlying flate stream after every write so
+// that each buffered NNTP command reaches the wire as soon as it is
+// flushed, rather than sitting in the compressor's internal buffer.
+type flateWriter struct {
+    fw *flate.Writer
+}
+
+func (w *flateWriter) Write(p []byte) (int, error) {
+    n, err := w.fw.Write(p)
+    if err != nil {
+        return n, err
+    }
+    return n, w.fw.Flush()
+}
+
+// flateConn combines a flate reader and writer over the same
+// underlying connection so it can be passed to wrap.
+type flateConn struct {
+    io.ReadCloser
+    *flateWriter
+}
+
+// EnableCompression negotiates RFC 8054 COMPRESS DEFLATE and wraps the
+// rest of the session in a flate reader/writer pair. Callers should
+// check Capabilities for "COMPRESS DEFLATE" first.
+func (c *Conn) EnableCompression() error {
+    if _, err := c.cmd([]int{206}, "COMPRESS DEFLATE"); err != nil {
+        return err
+    }
+    fw, err := flate.NewWriter(c.conn, flate.DefaultCompression)
+    if err != nil {
+        return fmt.Errorf("nntp: compress: %v", err)
+    }
+    c.wrap(flateConn{
+        ReadCloser:  flate.NewReader(c.conn),
+        flateWriter: &flateWriter{fw: fw},
+    })
+    return nil
+}
+
+// StartTLS upgrades an already-open plaintext connection using the
+// STARTTLS command (382 to proceed with the handshake). Callers should
+// check Capabilities for "STARTTLS" first.
+func (c *Conn) StartTLS(config *tls.Config) error {
+    if _, err := c.cmd([]int{382}, "STARTTLS"); err != nil {
+        return err
+    }
+    tlsConn := tls.Client(c.conn, config)
+    if err := tlsConn.Handshake(); err != nil {
+        return fmt.Errorf("nntp: starttls handshake: %v", err)
+    }
+    c.conn = tlsConn
+    c.wrap(tlsConn)
+    return nil
+}
+
+// sendCmd writes a command line, terminated by CRLF, and flushes it.
+func (c *Conn) sendCmd(format string, args ...interface{}) error {
+    line := fmt.Sprintf(format, args...)
+    if c.logger != nil {
+        c.logger.Debug("nntp: sent command", "line", redactCommand(line))
+    }
+    if _, err := fmt.Fprintf(c.w, "%s\r\n", line); err != nil {
+        return err
+    }
+    return c.w.Flush()
+}
+
+// redactCommand masks the password argument of an AUTHINFO PASS
+// command so it never reaches a log file.
+func redactCommand(line string) string {
+    const prefix = "AUTHINFO PASS "
+    if len(line) >= len(prefix) && strings.EqualFold(line[:len(prefix)], prefix) {
+        return "AUTHINFO PASS ****"
+    }
+    return line
+}
+
+// readCode reads a single status line and splits it into its numeric
+// code and message text.
+func (c *Conn) readCode() (code int, msg string, err error) {
+    line, err := c.r.ReadLine()
+    if err != nil {
+        return 0, "", err
+    }
+    if len(line) < 3 {
+        return 0, "", ProtocolError("short response: " + line)
+    }
+    code, err = strconv.Atoi(line[:3])
+    if err != nil {
+        return 0, "", ProtocolError("invalid response code: " + line)
+    }
+    msg = strings.TrimSpace(line[3:])
+    if c.logger != nil {
+        c.logger.Debug("nntp: received response", "code", code, "msg", msg)
+    }
+    return code, msg, nil
+}
+
+// expectCode reads a status line and returns an *Error unless the code
+// matches one of want.
+func (c *Conn) expectCode(want ...int) (msg string, err error) {
+    code, msg, err := c.readCode()
+    if err != nil {
+        return "", err
+    }
+    for _, w := range want {
+        if code == w {
+            return msg, nil
+        }
+    }
+    return "", &Error{Code: code, Msg: msg}
+}
+
+// readDotLines reads a multi-line response body terminated by a line
+// containing only ".", with dot-stuffing removed. This is the one place
+// in the package that deals with that encoding.
+func (c *Conn) readDotLines() ([]string, error) {
+    return c.r.ReadDotLines()
+}
+
+// cmd sends a command and returns the status line's message on success,
+// treating any code in want as success.
+func (c *Conn) cmd(want []int, format string, args ...interface{}) (string, error) {
+    if err := c.sendCmd(format, args...); err != nil {
+        return "", err
+    }
+    return c.expectCode(want...)
+}
+
+// Authenticate performs AUTHINFO USER/PASS authentication.
+func (c *Conn) Authenticate(user, pass string) error {
+    if _, err := c.cmd([]int{281, 381}, "AUTHINFO USER %s", user); err != nil {
+        if _, ok := err.(*Error); ok {
+            return err
+        }
+        return fmt.Errorf("nntp: auth user: %v", err)
+    }
+    if _, err := c.cmd([]int{281}, "AUTHINFO PASS %s", pass); err != nil {
+        return fmt.Errorf("nntp: auth pass: %v", err)
+    }
+    return nil
+}
+
+// Capabilities returns the list of capabilities the server advertises.
+func (c *Conn) Capabilities() ([]string, error) {
+    if _, err := c.cmd([]int{101}, "CAPABILITIES"); err != nil {
+        return nil, err
+    }
+    return c.readDotLines()
+}
+
+// Date returns the server's current time, as reported by the DATE
+// command.
+func (c *Conn) Date() (time.Time, error) {
+    msg, err := c.cmd([]int{111}, "DATE")
+    if err != nil {
+        return time.Time{}, err
+    }
+    return time.Parse("20060102150405", strings.TrimSpace(msg))
+}
+
+// List returns the active newsgroup list (LIST or LIST ACTIVE).
+func (c *Conn) List() ([]string, error) {
+    if _, err := c.cmd([]int{215}, "LIST"); err != nil {
+        return nil, err
+    }
+    return c.readDotLines()
+}
+
+// Group selects a newsgroup and returns the estimated article count and
+// the low and high article numbers, as reported by the GROUP command.
+func (c *Conn) Group(name string) (number, low, high int, err error) {
+    msg, err := c.cmd([]int{211}, "GROUP %s", name)
+    if err != nil {
+        return 0, 0, 0, err
+    }
+    fields := strings.Fields(msg)
+    if len(fields) < 3 {
+        return 0, 0, 0, ProtocolError("invalid GROUP response: " + msg)
+    }
+    if number, err = strconv.Atoi(fields[0]); err != nil {
+        return 0, 0, 0, ProtocolError("invalid article count: " + msg)
+    }
+    if low, err = strconv.Atoi(fields[1]); err != nil {
+        return 0, 0, 0, ProtocolError("invalid low mark: " + msg)
+    }
+    if high, err = strconv.Atoi(fields[2]); err != nil {
+        return 0, 0, 0, ProtocolError("invalid high mark: " + msg)
+    }
+    return number, low, high, nil
+}
+
+// article issues cmdName ("ARTICLE", "HEAD" or "BODY") for id, which
+// may be an article number or a "<message-id>", and returns the raw
+// dot-unstuffed lines of the response.
+func (c *Conn) article(cmdName, id string, code int) ([]string, error) {
+    if _, err := c.cmd([]int{code}, "%s %s", cmdName, id); err != nil {
+        return nil, err
+    }
+    return c.readDotLines()
+}
+
+// Article fetches the full article (headers and body) for id.
+func (c *Conn) Article(id string) (*Article, error) {
+    lines, err := c.article("ARTICLE", id, 220)
+    if err != nil {
+        return nil, err
+    }
+    return splitArticle(lines), nil
+}
+
+// Head fetches only the headers of the article identified by id.
+func (c *Conn) Head(id string) (*Article, error) {
+    lines, err := c.article("HEAD", id, 221)
+    if err != nil {
+        return nil, err
+    }
+    return &Article{Header: lines}, nil
+}
+
+// Body fetches only the body of the article identified by id.
+func (c *Conn) Body(id string) (string, error) {
+    lines, err := c.article("BODY", id, 222)
+    if err != nil {
+        return "", err
+    }
+    return strings.Join(lines, "\r\n") + "\r\n", nil
+}
+
+func splitArticle(lines []string) *Article {
+    for i, line := range lines {
+        if line == "" {
+            return &Article{
+                Header: lines[:i],
+                Body:   strings.Join(lines[i+1:], "\r\n") + "\r\n",
+            }
+        }
+    }
+    return &Article{Header: lines}
+}
+
+// Over issues an XOVER command for the given article range (e.g.
+// "100-200" or "100-") and parses the tab-separated overview lines.
+func (c *Conn) Over(spec string) ([]OverviewLine, error) {
+    if _, err := c.cmd([]int{224}, "XOVER %s", spec); err != nil {
+        return nil, err
+    }
+    lines, err := c.readDotLines()
+    if err != nil {
+        return nil, err
+    }
+    overview := make([]OverviewLine, 0, len(lines))
+    for _, line := range lines {
+        fields := strings.Split(line, "\t")
+        if len(fields) < 8 {
+            continue
+        }
+        number, err := strconv.Atoi(fields[0])
+        if err != nil {
+            continue
+        }
+        bytes, _ := strconv.Atoi(fields[6])
+        nlines, _ := strconv.Atoi(fields[7])
+        overview = append(overview, OverviewLine{
+            Number:     number,
+            Subject:    fields[1],
+            From:       fields[2],
+            Date:       fields[3],
+            MessageID:  fields[4],
+            References: fields[5],
+            Bytes:      bytes,
+            Lines:      nlines,
+        })
+    }
+    return overview, nil
+}
+
+// Next advances the current article pointer and returns the new
+// article number and message-id, per the NEXT command.
+func (c *Conn) Next() (number int, msgID string, err error) {
+    return c.advance("NEXT")
+}
+
+// Last moves the current article pointer back and returns the new
+// article number and message-id, per the LAST command.
+func (c *Conn) Last() (number int, msgID string, err error) {
+    return c.advance("LAST")
+}
+
+func (c *Conn) advance(cmdName string) (number int, msgID string, err error) {
+    msg, err := c.cmd([]int{223}, "%s", cmdName)
+    if err != nil {
+        return 0, "", err
+    }
+    fields := strings.Fields(msg)
+    if len(fields) < 2 {
+        return 0, "", ProtocolError("invalid " + cmdName + " response: " + msg)
+    }
+    number, err = strconv.Atoi(fields[0])
+    if err != nil {
+        return 0, "", ProtocolError("invalid article number: " + msg)
+    }
+    return number, fields[1], nil
+}
+
+// ModeReader puts the connection into "reader" mode, as required by
+// some servers before GROUP/ARTICLE commands are accepted.
+func (c *Conn) ModeReader() error {
+    _, err := c.cmd([]int{200, 201}, "MODE READER")
+    return err
+}
+
+// Quit sends QUIT and closes the underlying connection.
+func (c *Conn) Quit() error {
+    _, err := c.cmd([]int{205}, "QUIT")
+    c.conn.Close()
+    return err
+}
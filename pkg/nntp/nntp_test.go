@@ -0,0 +1,73 @@
+package nntp
+
+import (
+    "bufio"
+    "io"
+    "net/textproto"
+    "strings"
+    "testing"
+)
+
+// newTestConn builds a Conn whose reads come from script (CRLF-joined
+// lines) and whose writes are discarded.
+func newTestConn(t *testing.T, script string) *Conn {
+    t.Helper()
+    return &Conn{
+        conn: nil,
+        r:    textproto.NewReader(bufio.NewReader(strings.NewReader(script))),
+        w:    bufio.NewWriter(io.Discard),
+    }
+}
+
+func TestGroup(t *testing.T) {
+    c := newTestConn(t, "211 3000 1 3000 alt.test\r\n")
+    number, low, high, err := c.Group("alt.test")
+    if err != nil {
+        t.Fatalf("Group: %v", err)
+    }
+    if number != 3000 || low != 1 || high != 3000 {
+        t.Fatalf("Group = %d, %d, %d; want 3000, 1, 3000", number, low, high)
+    }
+}
+
+func TestGroupError(t *testing.T) {
+    c := newTestConn(t, "411 no such group\r\n")
+    if _, _, _, err := c.Group("alt.nonexistent"); err == nil {
+        t.Fatal("expected error for nonexistent group")
+    }
+}
+
+func TestArticleSplitsHeaderAndBody(t *testing.T) {
+    c := newTestConn(t,
+        "220 1 <id@test> article\r\n"+
+            "Subject: hello\r\n"+
+            "From: a@b.c\r\n"+
+            "\r\n"+
+            "line one\r\n"+
+            "..dot-stuffed\r\n"+
+            ".\r\n")
+    a, err := c.Article("1")
+    if err != nil {
+        t.Fatalf("Article: %v", err)
+    }
+    if len(a.Header) != 2 || a.Header[0] != "Subject: hello" {
+        t.Fatalf("Header = %#v", a.Header)
+    }
+    if want := "line one\r\n.dot-stuffed\r\n"; a.Body != want {
+        t.Fatalf("Body = %q, want %q", a.Body, want)
+    }
+}
+
+func TestOver(t *testing.T) {
+    c := newTestConn(t,
+        "224 overview information follows\r\n"+
+            "1\tsubj\tauthor\tdate\t<id>\t\t100\t10\r\n"+
+            ".\r\n")
+    lines, err := c.Over("1-1")
+    if err != nil {
+        t.Fatalf("Over: %v", err)
+    }
+    if len(lines) != 1 || lines[0].Subject != "subj" || lines[0].Bytes != 100 {
+        t.Fatalf("Over = %#v", lines)
+    }
+}
@@ -0,0 +1,53 @@
+package nntp
+
+import (
+    "io"
+    "net/textproto"
+)
+
+// postArticle writes a's headers and body as a single dot-terminated
+// block, applying dot-stuffing via textproto.Writer.DotWriter.
+func (c *Conn) postArticle(a *Article) error {
+    dw := textproto.NewWriter(c.w).DotWriter()
+    for _, h := range a.Header {
+        if _, err := io.WriteString(dw, h+"\r\n"); err != nil {
+            dw.Close()
+            return err
+        }
+    }
+    if _, err := io.WriteString(dw, "\r\n"); err != nil {
+        dw.Close()
+        return err
+    }
+    if _, err := io.WriteString(dw, a.Body); err != nil {
+        dw.Close()
+        return err
+    }
+    return dw.Close()
+}
+
+// Post submits a new article via the POST command.
+func (c *Conn) Post(a *Article) error {
+    if _, err := c.cmd([]int{340}, "POST"); err != nil {
+        return err
+    }
+    if err := c.postArticle(a); err != nil {
+        return err
+    }
+    _, err := c.expectCode(240)
+    return err
+}
+
+// IHave offers an article with the given Message-ID via the IHAVE
+// command, for peer-to-peer feeds. The server may reject it with 435
+// (not wanted) or 436 (try again later) before any data is sent.
+func (c *Conn) IHave(msgID string, a *Article) error {
+    if _, err := c.cmd([]int{335}, "IHAVE %s", msgID); err != nil {
+        return err
+    }
+    if err := c.postArticle(a); err != nil {
+        return err
+    }
+    _, err := c.expectCode(235)
+    return err
+}
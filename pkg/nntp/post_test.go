@@ -0,0 +1,83 @@
+package nntp
+
+import (
+    "bufio"
+    "bytes"
+    "net/textproto"
+    "strings"
+    "testing"
+)
+
+// newCapturingTestConn is like newTestConn but keeps the bytes written
+// to the server so postArticle's dot-stuffing and terminator can be
+// checked.
+func newCapturingTestConn(t *testing.T, script string) (*Conn, *bytes.Buffer) {
+    t.Helper()
+    var out bytes.Buffer
+    c := &Conn{
+        conn: nil,
+        r:    textproto.NewReader(bufio.NewReader(strings.NewReader(script))),
+        w:    bufio.NewWriter(&out),
+    }
+    return c, &out
+}
+
+func TestPostWritesDotStuffedArticle(t *testing.T) {
+    c, out := newCapturingTestConn(t, "340 send article\r\n240 article posted\r\n")
+
+    a := &Article{
+        Header: []string{"Subject: hello", "From: a@b.c"},
+        Body:   "line one\r\n.dot-stuffed\r\n",
+    }
+    if err := c.Post(a); err != nil {
+        t.Fatalf("Post: %v", err)
+    }
+
+    want := "POST\r\n" +
+        "Subject: hello\r\n" +
+        "From: a@b.c\r\n" +
+        "\r\n" +
+        "line one\r\n" +
+        "..dot-stuffed\r\n" +
+        ".\r\n"
+    if out.String() != want {
+        t.Fatalf("wrote %q, want %q", out.String(), want)
+    }
+}
+
+func TestPostPropagatesRejection(t *testing.T) {
+    c, _ := newCapturingTestConn(t, "441 posting not allowed\r\n")
+    a := &Article{Header: []string{"Subject: hello"}, Body: "body\r\n"}
+    if err := c.Post(a); err == nil {
+        t.Fatal("expected error for posting rejection")
+    }
+}
+
+func TestIHaveWritesMessageIDAndArticle(t *testing.T) {
+    c, out := newCapturingTestConn(t, "335 send article\r\n235 article transferred\r\n")
+
+    a := &Article{Header: []string{"Subject: hello"}, Body: "body\r\n"}
+    if err := c.IHave("<1@example.com>", a); err != nil {
+        t.Fatalf("IHave: %v", err)
+    }
+
+    want := "IHAVE <1@example.com>\r\n" +
+        "Subject: hello\r\n" +
+        "\r\n" +
+        "body\r\n" +
+        ".\r\n"
+    if out.String() != want {
+        t.Fatalf("wrote %q, want %q", out.String(), want)
+    }
+}
+
+func TestIHaveRejectedBeforeSending(t *testing.T) {
+    c, out := newCapturingTestConn(t, "435 article not wanted\r\n")
+    a := &Article{Header: []string{"Subject: hello"}, Body: "body\r\n"}
+    if err := c.IHave("<1@example.com>", a); err == nil {
+        t.Fatal("expected error for IHAVE rejection")
+    }
+    if want := "IHAVE <1@example.com>\r\n"; out.String() != want {
+        t.Fatalf("wrote %q, want %q (article should not be sent after a 435/436)", out.String(), want)
+    }
+}
@@ -0,0 +1,61 @@
+package sink
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "path/filepath"
+    "time"
+)
+
+// maildirSink delivers articles into a Maildir, writing to tmp/ first
+// and only making a message visible with an atomic rename into new/, as
+// the Maildir spec requires.
+type maildirSink struct {
+    dir string
+}
+
+func newMaildirSink(dir string) (Sink, error) {
+    for _, sub := range []string{"tmp", "new", "cur"} {
+        if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+            return nil, fmt.Errorf("maildir: %v", err)
+        }
+    }
+    return &maildirSink{dir: dir}, nil
+}
+
+func (s *maildirSink) Write(article []byte, _ map[string]string) error {
+    name, err := uniqueName()
+    if err != nil {
+        return fmt.Errorf("maildir: %v", err)
+    }
+    tmpPath := filepath.Join(s.dir, "tmp", name)
+    newPath := filepath.Join(s.dir, "new", name)
+
+    if err := os.WriteFile(tmpPath, article, 0644); err != nil {
+        return fmt.Errorf("maildir: write tmp: %v", err)
+    }
+    if err := os.Rename(tmpPath, newPath); err != nil {
+        os.Remove(tmpPath)
+        return fmt.Errorf("maildir: deliver: %v", err)
+    }
+    return nil
+}
+
+func (s *maildirSink) Close() error { return nil }
+
+// uniqueName builds a filename unique per the Maildir spec: a
+// timestamp, the pid, some randomness, and the hostname.
+func uniqueName() (string, error) {
+    var r [8]byte
+    if _, err := rand.Read(r[:]); err != nil {
+        return "", err
+    }
+    host, err := os.Hostname()
+    if err != nil {
+        host = "localhost"
+    }
+    now := time.Now()
+    return fmt.Sprintf("%d.M%dP%d_%s.%s", now.Unix(), now.Nanosecond()/1000, os.Getpid(), hex.EncodeToString(r[:]), host), nil
+}
@@ -0,0 +1,72 @@
+package sink
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestMaildirWriteDeliversToNew(t *testing.T) {
+    dir := t.TempDir()
+    s, err := newMaildirSink(dir)
+    if err != nil {
+        t.Fatalf("newMaildirSink: %v", err)
+    }
+
+    article := []byte("Subject: hi\r\n\r\nbody\r\n")
+    if err := s.Write(article, nil); err != nil {
+        t.Fatalf("Write: %v", err)
+    }
+
+    for _, sub := range []string{"tmp", "new", "cur"} {
+        if _, err := os.Stat(filepath.Join(dir, sub)); err != nil {
+            t.Fatalf("missing maildir subdirectory %s: %v", sub, err)
+        }
+    }
+
+    tmpEntries, err := os.ReadDir(filepath.Join(dir, "tmp"))
+    if err != nil {
+        t.Fatalf("ReadDir tmp: %v", err)
+    }
+    if len(tmpEntries) != 0 {
+        t.Fatalf("tmp/ = %v, want empty after delivery (message should be renamed into new/)", tmpEntries)
+    }
+
+    newEntries, err := os.ReadDir(filepath.Join(dir, "new"))
+    if err != nil {
+        t.Fatalf("ReadDir new: %v", err)
+    }
+    if len(newEntries) != 1 {
+        t.Fatalf("new/ has %d entries, want 1", len(newEntries))
+    }
+
+    got, err := os.ReadFile(filepath.Join(dir, "new", newEntries[0].Name()))
+    if err != nil {
+        t.Fatalf("ReadFile: %v", err)
+    }
+    if string(got) != string(article) {
+        t.Fatalf("delivered content = %q, want %q", got, article)
+    }
+}
+
+func TestMaildirWriteUsesUniqueNames(t *testing.T) {
+    dir := t.TempDir()
+    s, err := newMaildirSink(dir)
+    if err != nil {
+        t.Fatalf("newMaildirSink: %v", err)
+    }
+
+    for i := 0; i < 3; i++ {
+        if err := s.Write([]byte("article"), nil); err != nil {
+            t.Fatalf("Write %d: %v", i, err)
+        }
+    }
+
+    entries, err := os.ReadDir(filepath.Join(dir, "new"))
+    if err != nil {
+        t.Fatalf("ReadDir new: %v", err)
+    }
+    if len(entries) != 3 {
+        t.Fatalf("new/ has %d entries, want 3 uniquely-named messages", len(entries))
+    }
+}
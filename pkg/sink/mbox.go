@@ -0,0 +1,50 @@
+package sink
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "strings"
+    "time"
+)
+
+// mboxSink appends articles to a single mbox file, one per "From "
+// envelope line, with '>'-escaping of any body line that would
+// otherwise be mistaken for one (mboxo-style quoting).
+type mboxSink struct {
+    f *os.File
+    w *bufio.Writer
+}
+
+func newMboxSink(path string) (Sink, error) {
+    f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+    if err != nil {
+        return nil, fmt.Errorf("mbox: %v", err)
+    }
+    return &mboxSink{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (s *mboxSink) Write(article []byte, headers map[string]string) error {
+    from := headers["From"]
+    if from == "" {
+        from = "MAILER-DAEMON"
+    }
+    fmt.Fprintf(s.w, "From %s %s\n", from, time.Now().UTC().Format(time.ANSIC))
+
+    for _, line := range strings.Split(string(article), "\r\n") {
+        if strings.HasPrefix(line, "From ") {
+            line = ">" + line
+        }
+        fmt.Fprintf(s.w, "%s\n", line)
+    }
+    fmt.Fprintln(s.w)
+    return s.w.Flush()
+}
+
+func (s *mboxSink) Close() error {
+    if err := s.w.Flush(); err != nil {
+        s.f.Close()
+        return err
+    }
+    return s.f.Close()
+}
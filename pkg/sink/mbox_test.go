@@ -0,0 +1,71 @@
+package sink
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestMboxWriteEscapesFromLines(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "mail.mbox")
+    s, err := newMboxSink(path)
+    if err != nil {
+        t.Fatalf("newMboxSink: %v", err)
+    }
+
+    article := "Subject: hi\r\nFrom: a@b.c\r\n\r\nFrom the desk of someone\r\nordinary line\r\n"
+    if err := s.Write([]byte(article), ParseHeaders(strings.Split(article, "\r\n"))); err != nil {
+        t.Fatalf("Write: %v", err)
+    }
+    if err := s.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("ReadFile: %v", err)
+    }
+    lines := strings.Split(string(data), "\n")
+
+    if !strings.HasPrefix(lines[0], "From a@b.c ") {
+        t.Fatalf("envelope line = %q, want prefix %q", lines[0], "From a@b.c ")
+    }
+    if !contains(lines, ">From the desk of someone") {
+        t.Fatalf("body lines = %#v, want an escaped >From line", lines)
+    }
+    if contains(lines, "From the desk of someone") {
+        t.Fatalf("body lines = %#v, unescaped From line leaked through", lines)
+    }
+}
+
+func TestMboxWriteDefaultsEnvelopeSender(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "mail.mbox")
+    s, err := newMboxSink(path)
+    if err != nil {
+        t.Fatalf("newMboxSink: %v", err)
+    }
+    defer s.Close()
+
+    if err := s.Write([]byte("Subject: hi\r\n\r\nbody\r\n"), map[string]string{}); err != nil {
+        t.Fatalf("Write: %v", err)
+    }
+    s.Close()
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("ReadFile: %v", err)
+    }
+    if !strings.HasPrefix(string(data), "From MAILER-DAEMON ") {
+        t.Fatalf("envelope = %q, want MAILER-DAEMON fallback", strings.SplitN(string(data), "\n", 2)[0])
+    }
+}
+
+func contains(lines []string, want string) bool {
+    for _, l := range lines {
+        if l == want {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,79 @@
+package sink
+
+import (
+    "fmt"
+    "strings"
+
+    amqp "github.com/rabbitmq/amqp091-go"
+
+    "github.com/nats-io/nats.go"
+)
+
+// amqpExchange is the topic exchange nw publishes to; routing keys are
+// the article's Newsgroups header so consumers can bind by group.
+const amqpExchange = "nw.articles"
+
+type amqpSink struct {
+    conn *amqp.Connection
+    ch   *amqp.Channel
+}
+
+func newAMQPSink(url string) (Sink, error) {
+    conn, err := amqp.Dial(url)
+    if err != nil {
+        return nil, fmt.Errorf("amqp: dial: %v", err)
+    }
+    ch, err := conn.Channel()
+    if err != nil {
+        conn.Close()
+        return nil, fmt.Errorf("amqp: channel: %v", err)
+    }
+    if err := ch.ExchangeDeclare(amqpExchange, "topic", true, false, false, false, nil); err != nil {
+        ch.Close()
+        conn.Close()
+        return nil, fmt.Errorf("amqp: declare exchange: %v", err)
+    }
+    return &amqpSink{conn: conn, ch: ch}, nil
+}
+
+func (s *amqpSink) Write(article []byte, headers map[string]string) error {
+    return s.ch.Publish(amqpExchange, headers["Newsgroups"], false, false, amqp.Publishing{
+        ContentType: "message/rfc822",
+        Body:        article,
+    })
+}
+
+func (s *amqpSink) Close() error {
+    s.ch.Close()
+    return s.conn.Close()
+}
+
+type natsSink struct {
+    nc *nats.Conn
+}
+
+func newNATSSink(url string) (Sink, error) {
+    nc, err := nats.Connect(url)
+    if err != nil {
+        return nil, fmt.Errorf("nats: connect: %v", err)
+    }
+    return &natsSink{nc: nc}, nil
+}
+
+func (s *natsSink) Write(article []byte, headers map[string]string) error {
+    return s.nc.Publish(natsSubject(headers["Newsgroups"]), article)
+}
+
+func (s *natsSink) Close() error {
+    return s.nc.Drain()
+}
+
+// natsSubject maps a (possibly cross-posted) Newsgroups header to a
+// single NATS subject; on a cross-post only the first group is used.
+func natsSubject(newsgroups string) string {
+    if newsgroups == "" {
+        return "nw.articles.misc"
+    }
+    first, _, _ := strings.Cut(newsgroups, ",")
+    return "nw.articles." + strings.TrimSpace(first)
+}
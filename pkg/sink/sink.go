@@ -0,0 +1,58 @@
+// Package sink defines destinations that fetched NNTP articles can be
+// written to, decoupling cmd/nw from the on-disk or on-wire format each
+// destination expects.
+package sink
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Sink is a destination for fetched articles. Write is called once per
+// article with its raw bytes (headers, a blank line, then body; already
+// dot-unstuffed and without any NNTP multi-line terminator) plus a
+// parsed view of its headers for sinks that route on them.
+type Sink interface {
+    Write(article []byte, headers map[string]string) error
+    Close() error
+}
+
+// New builds a Sink from an -output spec:
+//
+//	stdout          write to stdout (default)
+//	mbox:<path>     append to an mbox file
+//	maildir:<path>  deliver into a Maildir
+//	amqp://...      publish to an AMQP exchange, routing key = Newsgroups
+//	nats://...      publish to a NATS subject derived from Newsgroups
+func New(spec string) (Sink, error) {
+    if spec == "" || spec == "stdout" {
+        return newStdoutSink(), nil
+    }
+    switch {
+    case strings.HasPrefix(spec, "mbox:"):
+        return newMboxSink(strings.TrimPrefix(spec, "mbox:"))
+    case strings.HasPrefix(spec, "maildir:"):
+        return newMaildirSink(strings.TrimPrefix(spec, "maildir:"))
+    case strings.HasPrefix(spec, "amqp://"), strings.HasPrefix(spec, "amqps://"):
+        return newAMQPSink(spec)
+    case strings.HasPrefix(spec, "nats://"):
+        return newNATSSink(spec)
+    default:
+        return nil, fmt.Errorf("sink: unrecognized -output %q", spec)
+    }
+}
+
+// ParseHeaders turns an article's raw header lines into a single-valued
+// lookup keyed by header name, for sinks that need to route on e.g.
+// Newsgroups without re-parsing the article body.
+func ParseHeaders(lines []string) map[string]string {
+    headers := make(map[string]string, len(lines))
+    for _, line := range lines {
+        name, value, ok := strings.Cut(line, ":")
+        if !ok {
+            continue
+        }
+        headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+    }
+    return headers
+}
@@ -0,0 +1,16 @@
+package sink
+
+import "os"
+
+// stdoutSink is the default sink: it prints each article to stdout, as
+// nw always has, but without the NNTP ".\r\n" terminator line.
+type stdoutSink struct{}
+
+func newStdoutSink() Sink { return stdoutSink{} }
+
+func (stdoutSink) Write(article []byte, _ map[string]string) error {
+    _, err := os.Stdout.Write(article)
+    return err
+}
+
+func (stdoutSink) Close() error { return nil }
@@ -0,0 +1,299 @@
+// Package statestore tracks which NNTP articles nw has already fetched,
+// indexed by Message-ID rather than article number, so that a server
+// renumbering a group or an XOVER response with gaps can't produce
+// duplicate deliveries. It is backed by BoltDB, whose copy-on-write
+// transaction commit already gives the fsync-then-atomic-swap a
+// hand-rolled write-temp-then-rename would, so Store doesn't reimplement
+// that itself.
+package statestore
+
+import (
+    "encoding/binary"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/bits-and-blooms/bloom/v3"
+    bolt "go.etcd.io/bbolt"
+)
+
+const (
+    bloomEstimatedItems = 200_000
+    bloomFalsePositive  = 0.01
+)
+
+var (
+    groupsBucket = []byte("groups")
+    seenBucket   = []byte("seen")
+    highWaterKey = []byte("highwater")
+)
+
+// Store is a crash-safe record of fetched articles, with an in-memory
+// bloom filter per group so the common "definitely not fetched yet"
+// case can skip the database entirely.
+type Store struct {
+    db *bolt.DB
+
+    mu      sync.Mutex
+    filters map[string]*bloom.BloomFilter
+}
+
+// Open opens, creating if necessary, the statestore database at path.
+func Open(path string) (*Store, error) {
+    db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+    if err != nil {
+        return nil, fmt.Errorf("statestore: open: %v", err)
+    }
+    s := &Store{db: db, filters: make(map[string]*bloom.BloomFilter)}
+    if err := s.loadFilters(); err != nil {
+        db.Close()
+        return nil, err
+    }
+    return s, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+    return s.db.Close()
+}
+
+// loadFilters rebuilds every group's in-memory bloom filter from its
+// on-disk seen set and swaps it into s.filters under s.mu. Filters
+// aren't themselves persisted: after a restart an empty filter would
+// wrongly report "not seen" for every Message-ID already on disk, so
+// each must be rebuilt from
+// the source of truth before Seen can be trusted again. The rebuild
+// itself runs against a snapshot view with no lock held, so a
+// concurrent Seen/MarkSeen isn't blocked for the duration; only the
+// final swap into s.filters takes s.mu.
+func (s *Store) loadFilters() error {
+    filters := make(map[string]*bloom.BloomFilter)
+    err := s.db.View(func(tx *bolt.Tx) error {
+        groups := tx.Bucket(groupsBucket)
+        if groups == nil {
+            return nil
+        }
+        return groups.ForEach(func(name, _ []byte) error {
+            gb := groups.Bucket(name)
+            if gb == nil {
+                return nil
+            }
+            seen := gb.Bucket(seenBucket)
+            if seen == nil {
+                return nil
+            }
+            filter := bloom.NewWithEstimates(bloomEstimatedItems, bloomFalsePositive)
+            if err := seen.ForEach(func(msgID, _ []byte) error {
+                filter.Add(msgID)
+                return nil
+            }); err != nil {
+                return err
+            }
+            filters[string(name)] = filter
+            return nil
+        })
+    })
+    if err != nil {
+        return err
+    }
+
+    s.mu.Lock()
+    s.filters = filters
+    s.mu.Unlock()
+    return nil
+}
+
+func (s *Store) filterFor(group string) *bloom.BloomFilter {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    f, ok := s.filters[group]
+    if !ok {
+        f = bloom.NewWithEstimates(bloomEstimatedItems, bloomFalsePositive)
+        s.filters[group] = f
+    }
+    return f
+}
+
+func groupBucket(tx *bolt.Tx, group string, create bool) (*bolt.Bucket, error) {
+    var groups *bolt.Bucket
+    if create {
+        var err error
+        groups, err = tx.CreateBucketIfNotExists(groupsBucket)
+        if err != nil {
+            return nil, err
+        }
+        return groups.CreateBucketIfNotExists([]byte(group))
+    }
+    groups = tx.Bucket(groupsBucket)
+    if groups == nil {
+        return nil, nil
+    }
+    return groups.Bucket([]byte(group)), nil
+}
+
+// Seen reports whether msgID has already been fetched for group. The
+// bloom filter answers a "definitely not seen" without touching the
+// database; a possible match is confirmed against the real seen set,
+// since bloom filters can false-positive but never false-negative.
+func (s *Store) Seen(group, msgID string) (bool, error) {
+    if !s.filterFor(group).TestString(msgID) {
+        return false, nil
+    }
+    var seen bool
+    err := s.db.View(func(tx *bolt.Tx) error {
+        gb, err := groupBucket(tx, group, false)
+        if err != nil || gb == nil {
+            return err
+        }
+        b := gb.Bucket(seenBucket)
+        if b == nil {
+            return nil
+        }
+        seen = b.Get([]byte(msgID)) != nil
+        return nil
+    })
+    return seen, err
+}
+
+// MarkSeen records that msgID has been fetched for group, at when.
+func (s *Store) MarkSeen(group, msgID string, when time.Time) error {
+    err := s.db.Update(func(tx *bolt.Tx) error {
+        gb, err := groupBucket(tx, group, true)
+        if err != nil {
+            return err
+        }
+        b, err := gb.CreateBucketIfNotExists(seenBucket)
+        if err != nil {
+            return err
+        }
+        ts, err := when.MarshalBinary()
+        if err != nil {
+            return err
+        }
+        return b.Put([]byte(msgID), ts)
+    })
+    if err != nil {
+        return fmt.Errorf("statestore: mark seen: %v", err)
+    }
+    s.filterFor(group).AddString(msgID)
+    return nil
+}
+
+// HighWater returns the highest article number fetched for group, or 0
+// if nothing has been recorded yet.
+func (s *Store) HighWater(group string) (int, error) {
+    var n int
+    err := s.db.View(func(tx *bolt.Tx) error {
+        gb, err := groupBucket(tx, group, false)
+        if err != nil || gb == nil {
+            return err
+        }
+        v := gb.Get(highWaterKey)
+        if v == nil {
+            return nil
+        }
+        n = int(binary.BigEndian.Uint64(v))
+        return nil
+    })
+    return n, err
+}
+
+// SetHighWater records the highest article number fetched so far for
+// group.
+func (s *Store) SetHighWater(group string, n int) error {
+    return s.db.Update(func(tx *bolt.Tx) error {
+        gb, err := groupBucket(tx, group, true)
+        if err != nil {
+            return err
+        }
+        buf := make([]byte, 8)
+        binary.BigEndian.PutUint64(buf, uint64(n))
+        return gb.Put(highWaterKey, buf)
+    })
+}
+
+// Groups lists every newsgroup with state on record.
+func (s *Store) Groups() ([]string, error) {
+    var groups []string
+    err := s.db.View(func(tx *bolt.Tx) error {
+        b := tx.Bucket(groupsBucket)
+        if b == nil {
+            return nil
+        }
+        return b.ForEach(func(name, _ []byte) error {
+            groups = append(groups, string(name))
+            return nil
+        })
+    })
+    return groups, err
+}
+
+// Count returns the number of seen Message-IDs recorded for group.
+func (s *Store) Count(group string) (int, error) {
+    var n int
+    err := s.db.View(func(tx *bolt.Tx) error {
+        gb, err := groupBucket(tx, group, false)
+        if err != nil || gb == nil {
+            return err
+        }
+        b := gb.Bucket(seenBucket)
+        if b == nil {
+            return nil
+        }
+        n = b.Stats().KeyN
+        return nil
+    })
+    return n, err
+}
+
+// Prune removes seen-article records older than olderThan across every
+// group and returns how many were removed.
+func (s *Store) Prune(olderThan time.Duration) (int, error) {
+    cutoff := time.Now().Add(-olderThan)
+    removed := 0
+    err := s.db.Update(func(tx *bolt.Tx) error {
+        groups := tx.Bucket(groupsBucket)
+        if groups == nil {
+            return nil
+        }
+        return groups.ForEach(func(name, _ []byte) error {
+            gb := groups.Bucket(name)
+            if gb == nil {
+                return nil
+            }
+            b := gb.Bucket(seenBucket)
+            if b == nil {
+                return nil
+            }
+
+            var stale [][]byte
+            if err := b.ForEach(func(msgID, ts []byte) error {
+                var t time.Time
+                if err := t.UnmarshalBinary(ts); err != nil {
+                    return nil // Corrupt timestamp: leave the record rather than lose the dedup entry.
+                }
+                if t.Before(cutoff) {
+                    stale = append(stale, append([]byte(nil), msgID...))
+                }
+                return nil
+            }); err != nil {
+                return err
+            }
+            for _, msgID := range stale {
+                if err := b.Delete(msgID); err != nil {
+                    return err
+                }
+                removed++
+            }
+            return nil
+        })
+    })
+    if err != nil {
+        return removed, fmt.Errorf("statestore: prune: %v", err)
+    }
+
+    if err := s.loadFilters(); err != nil {
+        return removed, err
+    }
+    return removed, nil
+}
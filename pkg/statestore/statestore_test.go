@@ -0,0 +1,177 @@
+package statestore
+
+import (
+    "fmt"
+    "path/filepath"
+    "sync"
+    "testing"
+    "time"
+)
+
+func openTestStore(t *testing.T) *Store {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "state.db")
+    s, err := Open(path)
+    if err != nil {
+        t.Fatalf("Open: %v", err)
+    }
+    t.Cleanup(func() { s.Close() })
+    return s
+}
+
+func TestSeenMarkSeenRoundTrip(t *testing.T) {
+    s := openTestStore(t)
+
+    seen, err := s.Seen("alt.test", "<1@example.com>")
+    if err != nil {
+        t.Fatalf("Seen: %v", err)
+    }
+    if seen {
+        t.Fatalf("Seen = true before MarkSeen")
+    }
+
+    if err := s.MarkSeen("alt.test", "<1@example.com>", time.Now()); err != nil {
+        t.Fatalf("MarkSeen: %v", err)
+    }
+
+    seen, err = s.Seen("alt.test", "<1@example.com>")
+    if err != nil {
+        t.Fatalf("Seen: %v", err)
+    }
+    if !seen {
+        t.Fatalf("Seen = false after MarkSeen")
+    }
+
+    seen, err = s.Seen("alt.test", "<2@example.com>")
+    if err != nil {
+        t.Fatalf("Seen: %v", err)
+    }
+    if seen {
+        t.Fatalf("Seen = true for a message-ID that was never marked")
+    }
+}
+
+func TestSeenSurvivesReopen(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "state.db")
+
+    s, err := Open(path)
+    if err != nil {
+        t.Fatalf("Open: %v", err)
+    }
+    if err := s.MarkSeen("alt.test", "<1@example.com>", time.Now()); err != nil {
+        t.Fatalf("MarkSeen: %v", err)
+    }
+    if err := s.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+
+    s2, err := Open(path)
+    if err != nil {
+        t.Fatalf("reopen: %v", err)
+    }
+    defer s2.Close()
+
+    seen, err := s2.Seen("alt.test", "<1@example.com>")
+    if err != nil {
+        t.Fatalf("Seen: %v", err)
+    }
+    if !seen {
+        t.Fatalf("Seen = false after reopen; bloom filter should have been rebuilt from disk")
+    }
+}
+
+func TestHighWater(t *testing.T) {
+    s := openTestStore(t)
+
+    n, err := s.HighWater("alt.test")
+    if err != nil {
+        t.Fatalf("HighWater: %v", err)
+    }
+    if n != 0 {
+        t.Fatalf("HighWater = %d before any write, want 0", n)
+    }
+
+    if err := s.SetHighWater("alt.test", 42); err != nil {
+        t.Fatalf("SetHighWater: %v", err)
+    }
+
+    n, err = s.HighWater("alt.test")
+    if err != nil {
+        t.Fatalf("HighWater: %v", err)
+    }
+    if n != 42 {
+        t.Fatalf("HighWater = %d, want 42", n)
+    }
+}
+
+func TestPruneRemovesOnlyStaleEntries(t *testing.T) {
+    s := openTestStore(t)
+
+    if err := s.MarkSeen("alt.test", "<old@example.com>", time.Now().Add(-48*time.Hour)); err != nil {
+        t.Fatalf("MarkSeen old: %v", err)
+    }
+    if err := s.MarkSeen("alt.test", "<new@example.com>", time.Now()); err != nil {
+        t.Fatalf("MarkSeen new: %v", err)
+    }
+
+    removed, err := s.Prune(24 * time.Hour)
+    if err != nil {
+        t.Fatalf("Prune: %v", err)
+    }
+    if removed != 1 {
+        t.Fatalf("Prune removed %d entries, want 1", removed)
+    }
+
+    seen, err := s.Seen("alt.test", "<old@example.com>")
+    if err != nil {
+        t.Fatalf("Seen: %v", err)
+    }
+    if seen {
+        t.Fatalf("Seen = true for a pruned message-ID")
+    }
+
+    seen, err = s.Seen("alt.test", "<new@example.com>")
+    if err != nil {
+        t.Fatalf("Seen: %v", err)
+    }
+    if !seen {
+        t.Fatalf("Seen = false for a message-ID newer than the prune cutoff")
+    }
+}
+
+// TestConcurrentMarkSeenDuringPrune exercises the access pattern
+// cmd/nw's multi-connection pool uses against a shared *Store: workers
+// calling MarkSeen/Seen concurrently with a Prune rebuilding the bloom
+// filters. Run with -race to catch a filter map swapped in without
+// holding s.mu.
+func TestConcurrentMarkSeenDuringPrune(t *testing.T) {
+    s := openTestStore(t)
+
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            msgID := fmt.Sprintf("<%d@example.com>", i)
+            for j := 0; j < 50; j++ {
+                if err := s.MarkSeen("alt.test", msgID, time.Now()); err != nil {
+                    t.Errorf("MarkSeen: %v", err)
+                    return
+                }
+                if _, err := s.Seen("alt.test", msgID); err != nil {
+                    t.Errorf("Seen: %v", err)
+                    return
+                }
+            }
+        }(i)
+    }
+
+    for i := 0; i < 20; i++ {
+        if _, err := s.Prune(time.Hour); err != nil {
+            t.Errorf("Prune: %v", err)
+            return
+        }
+    }
+
+    wg.Wait()
+}